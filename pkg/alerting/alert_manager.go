@@ -16,11 +16,12 @@ package alerting
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"math"
-	"os"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
@@ -42,38 +43,150 @@ const (
 	sevError        severityLevel = "error"
 	sevCritical     severityLevel = "critical"
 	alertMetricName               = "alert"
-	rcAlert                       = 3
+	// RCAlert is the process exit code callers should use when a critical alert
+	// aborts the run
+	RCAlert = 3
 )
 
-// alertProfile expression list
-type alertProfile []struct {
+// criticalAlertAction controls what Evaluate does when a critical alert fires
+type criticalAlertAction string
+
+const (
+	// CriticalActionExit asks the caller to finish indexing/flushing and exit with RCAlert
+	CriticalActionExit criticalAlertAction = "exit"
+	// CriticalActionCancel asks the caller to cancel the run context but keep the process alive
+	CriticalActionCancel criticalAlertAction = "cancel"
+	// CriticalActionContinue only records critical alerts, the run is otherwise unaffected
+	CriticalActionContinue criticalAlertAction = "continue"
+)
+
+// ErrCriticalAlert is returned by Evaluate when a critical alert fires and
+// criticalAlertAction is not "continue". By then every alert of the evaluation has been
+// indexed and notified; the caller is expected to flush in-flight measurements and cancel
+// the run context before acting on CriticalAlertAction (e.g. exiting with RCAlert)
+var ErrCriticalAlert = errors.New("critical alert fired")
+
+// alertDuration wraps a time.Duration so it can be decoded from a Prometheus-style
+// duration string (e.g. "5m") by yaml.v3. model.Duration only implements go-yaml v2's
+// UnmarshalYAML(func(interface{}) error), which yaml.v3 never calls, so it can't be used
+// directly here without every "for: 5m" entry failing to decode
+type alertDuration time.Duration
+
+func (d *alertDuration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := model.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %s", value.Value, err)
+	}
+	*d = alertDuration(parsed)
+	return nil
+}
+
+// alertRule is a single entry of an alertProfile
+type alertRule struct {
 	// PromQL expression to evaluate
 	Expr string `yaml:"expr"`
 	// Informative comment reported when the alarm is triggered
 	Description string `yaml:"description"`
 	// Alert Severity
 	Severity severityLevel `yaml:"severity"`
+	// Minimum amount of time the expression has to hold true, per label set, before the alert fires
+	For alertDuration `yaml:"for"`
+	// Extra labels attached to the fired alert, templated the same way as description
+	Labels map[string]string `yaml:"labels"`
+	// Extra annotations attached to the fired alert, templated the same way as description
+	Annotations map[string]string `yaml:"annotations"`
+	// Severity to report instead of Severity when the alert falls within the job's churn window
+	ChurnSeverity severityLevel `yaml:"churnSeverity"`
+	// Drop alerts that fall within the job's churn window entirely, instead of reporting them
+	SuppressDuringChurn bool `yaml:"suppressDuringChurn"`
 }
 
+// alertProfile expression list
+type alertProfile []alertRule
+
 // alert definition
 type alert struct {
-	Timestamp   time.Time     `json:"timestamp"`
-	UUID        string        `json:"uuid"`
-	Severity    severityLevel `json:"severity"`
-	Description string        `json:"description"`
-	MetricName  string        `json:"metricName"`
-	ChurnMetric bool          `json:"churnMetric,omitempty"`
-	Metadata    any           `json:"metadata,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
+	UUID        string            `json:"uuid"`
+	Severity    severityLevel     `json:"severity"`
+	Description string            `json:"description"`
+	MetricName  string            `json:"metricName"`
+	// RuleName is a stable, rule-derived slug (from the rule's raw description), used by
+	// notifiers as a default alertname distinct from the generic MetricName
+	RuleName    string            `json:"ruleName,omitempty"`
+	ChurnMetric bool              `json:"churnMetric,omitempty"`
+	Metadata    any               `json:"metadata,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	For         string            `json:"for,omitempty"`
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify derives a stable, notifier-friendly identifier from a rule's raw description,
+// so alerts from different rules don't all collapse onto the same alertname
+func slugify(s string) string {
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if slug == "" {
+		return alertMetricName
+	}
+	return slug
+}
+
+// labelSetRun tracks a contiguous run of samples sharing the same label set
+type labelSetRun struct {
+	start time.Time
+	end   time.Time
+	value model.SampleValue
+}
+
+func (r labelSetRun) duration() time.Duration {
+	return r.end.Sub(r.start)
+}
+
+// alertManagerConfig is the on-disk shape of an alert profile: the list of rules to
+// evaluate plus an optional notifier sink fired alerts are pushed to
+type alertManagerConfig struct {
+	Alerts   alertProfile    `yaml:"alerts"`
+	Notifier *notifierConfig `yaml:"notifier"`
+	// What to do when a critical alert fires, defaults to CriticalActionExit
+	CriticalAlertAction criticalAlertAction `yaml:"criticalAlertAction"`
+}
+
+// decodeAlertManagerConfig accepts both the pre-notifier profile shape, a bare list of
+// alert rules, and the current {alerts, notifier, criticalAlertAction} object shape, so
+// existing profiles keep working unchanged
+func decodeAlertManagerConfig(raw []byte) (alertManagerConfig, error) {
+	var legacy alertProfile
+	legacyDec := yaml.NewDecoder(bytes.NewReader(raw))
+	legacyDec.KnownFields(true)
+	if err := legacyDec.Decode(&legacy); err == nil {
+		return alertManagerConfig{Alerts: legacy}, nil
+	}
+	var cfg alertManagerConfig
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return alertManagerConfig{}, err
+	}
+	return cfg, nil
 }
 
 // AlertManager configuration
 type AlertManager struct {
-	alertProfile alertProfile
-	prometheus   *prometheus.Prometheus
-	indexer      *indexers.Indexer
-	uuid         string
-	metadata     any
-	embedCfg     *fileutils.EmbedConfiguration
+	alertProfile        alertProfile
+	notifier            Notifier
+	criticalAlertAction criticalAlertAction
+	prometheus          *prometheus.Prometheus
+	indexer             *indexers.Indexer
+	uuid                string
+	metadata            any
+	embedCfg            *fileutils.EmbedConfiguration
+}
+
+// CriticalAlertAction reports what the caller should do when Evaluate returns ErrCriticalAlert
+func (a *AlertManager) CriticalAlertAction() string {
+	return string(a.criticalAlertAction)
 }
 
 var baseTemplate = []string{
@@ -109,11 +222,22 @@ func (a *AlertManager) readProfile(alertProfileCfg string) error {
 	if err != nil {
 		return fmt.Errorf("error reading alert profile %s: %s", alertProfileCfg, err)
 	}
-	yamlDec := yaml.NewDecoder(f)
-	yamlDec.KnownFields(true)
-	if err = yamlDec.Decode(&a.alertProfile); err != nil {
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("error reading alert profile %s: %s", alertProfileCfg, err)
+	}
+	cfg, err := decodeAlertManagerConfig(raw)
+	if err != nil {
 		return fmt.Errorf("error decoding alert profile %s: %s", alertProfileCfg, err)
 	}
+	a.alertProfile = cfg.Alerts
+	a.criticalAlertAction = cfg.CriticalAlertAction
+	if a.criticalAlertAction == "" {
+		a.criticalAlertAction = CriticalActionExit
+	}
+	if a.notifier, err = newNotifier(cfg.Notifier); err != nil {
+		return fmt.Errorf("error configuring notifier for alert profile %s: %s", alertProfileCfg, err)
+	}
 	return a.validateTemplates()
 }
 
@@ -121,6 +245,7 @@ func (a *AlertManager) readProfile(alertProfileCfg string) error {
 func (a *AlertManager) Evaluate(job prometheus.Job) error {
 	errs := []error{}
 	var alertList []any
+	var criticalFired bool
 	var renderedQuery bytes.Buffer
 	if job.JobConfig.Name != "" {
 		log.Infof("Evaluating alerts for job %s in: %v", job.JobConfig.Name, a.prometheus.Endpoint)
@@ -141,64 +266,169 @@ func (a *AlertManager) Evaluate(job prometheus.Job) error {
 			log.Warnf("Error performing query %s: %s", expr, err)
 			continue
 		}
-		alertData, err := parseMatrix(v, a.uuid, alert.Description, a.metadata, alert.Severity, job.ChurnStart, job.ChurnEnd)
+		alertData, fired, err := parseMatrix(v, a.uuid, alert, a.metadata, job.ChurnStart, job.ChurnEnd, a.prometheus.Step, a.criticalAlertAction)
+		if fired {
+			criticalFired = true
+		}
 		if err != nil {
 			log.Error(err.Error())
 			errs = append(errs, err)
 		}
 		alertList = append(alertList, alertData...)
 	}
-	if len(alertList) > 0 && a.indexer != nil {
-		a.index(alertList)
+	if len(alertList) > 0 {
+		if a.indexer != nil {
+			a.index(alertList)
+		}
+		if a.notifier != nil {
+			if err := a.notify(alertList); err != nil {
+				log.Error(err.Error())
+				errs = append(errs, err)
+			}
+		}
+	}
+	if criticalFired && a.criticalAlertAction != CriticalActionContinue {
+		errs = append(errs, ErrCriticalAlert)
 	}
 	return utilerrors.NewAggregate(errs)
 }
 
+func (a *AlertManager) notify(alertList []any) error {
+	alerts := make([]alert, 0, len(alertList))
+	for _, a := range alertList {
+		alerts = append(alerts, a.(alert))
+	}
+	log.Infof("Notifying %d alerts", len(alerts))
+	return a.notifier.Notify(context.Background(), alerts)
+}
+
 func (a *AlertManager) validateTemplates() error {
 	for _, a := range a.alertProfile {
 		if _, err := template.New("").Parse(strings.Join(append(baseTemplate, a.Description), "")); err != nil {
 			return fmt.Errorf("template validation error '%s': %s", a.Description, err)
 		}
+		for _, m := range []map[string]string{a.Labels, a.Annotations} {
+			for k, v := range m {
+				if _, err := template.New("").Parse(strings.Join(append(baseTemplate, v), "")); err != nil {
+					return fmt.Errorf("template validation error '%s': %s", k, err)
+				}
+			}
+		}
 	}
 	return nil
 }
 
-func parseMatrix(value model.Value, uuid, description string, metadata any, severity severityLevel, churnStart, churnEnd *time.Time) ([]any, error) {
+// labelSetRuns groups values into contiguous runs, i.e. sequences of samples spaced by step,
+// which represent periods during which the expression evaluated truthy without interruption
+func labelSetRuns(values []model.SamplePair, step time.Duration) []labelSetRun {
+	var runs []labelSetRun
+	for _, val := range values {
+		ts := val.Timestamp.Time().UTC()
+		if n := len(runs); n > 0 && ts.Sub(runs[n-1].end) <= step {
+			runs[n-1].end = ts
+			runs[n-1].value = val.Value
+			continue
+		}
+		runs = append(runs, labelSetRun{start: ts, end: ts, value: val.Value})
+	}
+	return runs
+}
+
+func renderTemplates(templates map[string]string, data descriptionTemplate) (map[string]string, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	rendered := make(map[string]string, len(templates))
+	var buf bytes.Buffer
+	for k, v := range templates {
+		t, err := template.New("").Parse(strings.Join(append(baseTemplate, v), ""))
+		if err != nil {
+			return nil, fmt.Errorf("template validation error '%s': %s", k, err)
+		}
+		buf.Reset()
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("template rendering error '%s': %s", k, err)
+		}
+		rendered[k] = buf.String()
+	}
+	return rendered, nil
+}
+
+// parseMatrix renders alerts out of a queried matrix. The returned bool reports whether
+// a critical alert fired, so Evaluate can decide what to do once every rule has run
+func parseMatrix(value model.Value, uuid string, rule alertRule, metadata any, churnStart, churnEnd *time.Time, step time.Duration, action criticalAlertAction) ([]any, bool, error) {
 	var renderedDesc bytes.Buffer
 	var templateData descriptionTemplate
 	// The same query can fire multiple alerts, so we have to return an array of them
 	var alertSet []any
+	var criticalFired bool
 	errs := []error{}
-	t, _ := template.New("").Parse(strings.Join(append(baseTemplate, description), ""))
+	t, _ := template.New("").Parse(strings.Join(append(baseTemplate, rule.Description), ""))
 	data, ok := value.(model.Matrix)
 	if !ok {
-		return alertSet, fmt.Errorf("unsupported result format: %s", value.Type().String())
+		return alertSet, false, fmt.Errorf("unsupported result format: %s", value.Type().String())
 	}
+	forDuration := time.Duration(rule.For)
+	ruleName := slugify(rule.Description)
 	for _, v := range data {
 		templateData.Labels = make(map[string]string)
 		for k, v := range v.Metric {
 			templateData.Labels[string(k)] = string(v)
 		}
-		for _, val := range v.Values {
+		for _, run := range labelSetRuns(v.Values, step) {
+			if run.duration() < forDuration {
+				continue
+			}
+			inChurn := churnStart != nil && run.start.After(*churnStart) && run.start.Before(*churnEnd)
+			if inChurn && rule.SuppressDuringChurn {
+				continue
+			}
+			severity := rule.Severity
+			if inChurn && rule.ChurnSeverity != "" {
+				severity = rule.ChurnSeverity
+			}
 			renderedDesc.Reset()
 			// Take 3 decimals
-			templateData.Value = math.Round(float64(val.Value)*1000) / 1000
+			templateData.Value = math.Round(float64(run.value)*1000) / 1000
 			if err := t.Execute(&renderedDesc, templateData); err != nil {
 				msg := fmt.Errorf("alert rendering error: %s", err)
 				log.Error(msg.Error())
 				errs = append(errs, err)
 			}
-			msg := fmt.Sprintf("alert at %v: '%s'", val.Timestamp.Time().UTC().Format(time.RFC3339), renderedDesc.String())
+			labels, err := renderTemplates(rule.Labels, templateData)
+			if err != nil {
+				log.Error(err.Error())
+				errs = append(errs, err)
+			}
+			annotations, err := renderTemplates(rule.Annotations, templateData)
+			if err != nil {
+				log.Error(err.Error())
+				errs = append(errs, err)
+			}
+			msg := fmt.Sprintf("alert at %v: '%s'", run.start.Format(time.RFC3339), renderedDesc.String())
 			alert := alert{
 				UUID:        uuid,
 				Metadata:    metadata,
-				Timestamp:   val.Timestamp.Time().UTC(),
+				Timestamp:   run.start,
 				Severity:    severity,
 				Description: renderedDesc.String(),
 				MetricName:  alertMetricName,
+				RuleName:    ruleName,
+				Labels:      labels,
+				Annotations: annotations,
+				ChurnMetric: inChurn,
+			}
+			if forDuration > 0 {
+				alert.For = run.duration().String()
 			}
-			if churnStart != nil && alert.Timestamp.After(*churnStart) && alert.Timestamp.Before(*churnEnd) {
-				alert.ChurnMetric = true
+			if alert.Labels == nil {
+				alert.Labels = templateData.Labels
+			} else {
+				for k, v := range templateData.Labels {
+					if _, ok := alert.Labels[k]; !ok {
+						alert.Labels[k] = v
+					}
+				}
 			}
 			alertSet = append(alertSet, alert)
 			switch severity {
@@ -207,15 +437,20 @@ func parseMatrix(value model.Value, uuid, description string, metadata any, seve
 			case sevError:
 				errs = append(errs, errors.New(msg))
 			case sevCritical:
-				log.Errorf("🚨 %s", msg)
-				os.Exit(rcAlert)
+				criticalFired = true
+				if action == CriticalActionContinue {
+					// continue only records critical alerts, it must not fail the evaluation
+					log.Warnf("🚨 %s", msg)
+				} else {
+					log.Errorf("🚨 %s", msg)
+					errs = append(errs, errors.New(msg))
+				}
 			default:
 				log.Infof("🚨 %s", msg)
 			}
-			break
 		}
 	}
-	return alertSet, utilerrors.NewAggregate(errs)
+	return alertSet, criticalFired, utilerrors.NewAggregate(errs)
 }
 
 func (a *AlertManager) index(alertSet []any) {
@@ -0,0 +1,64 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestParseMatrixChurnSuppression(t *testing.T) {
+	step := 30 * time.Second
+	churnStart := time.Unix(1700000000, 0).UTC()
+	churnEnd := churnStart.Add(5 * time.Minute)
+	sampleTime := churnStart.Add(time.Minute)
+	matrix := model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"pod": "foo"},
+			Values: []model.SamplePair{sampleAt(sampleTime, 1)},
+		},
+	}
+	rule := alertRule{
+		Description:         "value {{$value}}",
+		Severity:            sevError,
+		SuppressDuringChurn: true,
+	}
+	alerts, _, err := parseMatrix(matrix, "uuid", rule, nil, &churnStart, &churnEnd, step, CriticalActionExit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected the alert to be suppressed during churn, got %d", len(alerts))
+	}
+
+	rule.SuppressDuringChurn = false
+	rule.ChurnSeverity = sevWarn
+	alerts, _, err = parseMatrix(matrix, "uuid", rule, nil, &churnStart, &churnEnd, step, CriticalActionExit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %d", len(alerts))
+	}
+	got := alerts[0].(alert)
+	if got.Severity != sevWarn {
+		t.Errorf("expected churn severity override %q, got %q", sevWarn, got.Severity)
+	}
+	if !got.ChurnMetric {
+		t.Errorf("expected ChurnMetric to be set")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestParseMatrixCriticalAction(t *testing.T) {
+	step := 30 * time.Second
+	base := time.Unix(1700000000, 0).UTC()
+	matrix := model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"pod": "foo"},
+			Values: []model.SamplePair{sampleAt(base, 1)},
+		},
+	}
+	rule := alertRule{Description: "critical {{$value}}", Severity: sevCritical}
+
+	_, critical, err := parseMatrix(matrix, "uuid", rule, nil, nil, nil, step, CriticalActionContinue)
+	if err != nil {
+		t.Fatalf("continue mode must not surface a critical alert as an error, got %s", err)
+	}
+	if !critical {
+		t.Fatalf("expected the critical alert to still be reported as fired")
+	}
+
+	_, critical, err = parseMatrix(matrix, "uuid", rule, nil, nil, nil, step, CriticalActionExit)
+	if err == nil {
+		t.Fatalf("exit mode must surface a critical alert as an error")
+	}
+	if !critical {
+		t.Fatalf("expected the critical alert to be reported as fired")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func sampleAt(ts time.Time, value float64) model.SamplePair {
+	return model.SamplePair{Timestamp: model.TimeFromUnixNano(ts.UnixNano()), Value: model.SampleValue(value)}
+}
+
+func TestLabelSetRuns(t *testing.T) {
+	step := 30 * time.Second
+	base := time.Unix(1700000000, 0).UTC()
+	values := []model.SamplePair{
+		sampleAt(base, 1),
+		sampleAt(base.Add(step), 1),
+		sampleAt(base.Add(2*step), 1),
+		// a gap bigger than step starts a new run
+		sampleAt(base.Add(5*step), 1),
+	}
+	runs := labelSetRuns(values, step)
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if got := runs[0].duration(); got != 2*step {
+		t.Errorf("expected first run duration %v, got %v", 2*step, got)
+	}
+	if got := runs[1].duration(); got != 0 {
+		t.Errorf("expected second run duration 0, got %v", got)
+	}
+}
+
+func TestParseMatrixForDuration(t *testing.T) {
+	step := 30 * time.Second
+	base := time.Unix(1700000000, 0).UTC()
+	matrix := model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"pod": "foo"},
+			Values: []model.SamplePair{
+				sampleAt(base, 1),
+				sampleAt(base.Add(step), 1),
+			},
+		},
+	}
+	rule := alertRule{
+		Description: "high value {{$value}}",
+		Severity:    sevWarn,
+		For:         alertDuration(step),
+	}
+	alerts, critical, err := parseMatrix(matrix, "uuid", rule, nil, nil, nil, step, CriticalActionExit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if critical {
+		t.Fatalf("expected no critical alert")
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert once the run satisfies for, got %d", len(alerts))
+	}
+
+	// Requiring longer than the observed run must not fire
+	rule.For = alertDuration(2 * step)
+	alerts, _, err = parseMatrix(matrix, "uuid", rule, nil, nil, nil, step, CriticalActionExit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alert below the for duration, got %d", len(alerts))
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// notifierType selects the sink implementation a notifierConfig targets
+type notifierType string
+
+const (
+	notifierWebhook      notifierType = "webhook"
+	notifierAlertmanager notifierType = "alertmanager"
+)
+
+// notifierConfig configures the sink alerts are pushed to once they're fired
+type notifierConfig struct {
+	// Type of notifier, webhook or alertmanager
+	Type notifierType `yaml:"type"`
+	// Endpoint alerts are pushed to
+	URL string `yaml:"url"`
+	// Extra headers added to every request
+	Headers map[string]string `yaml:"headers"`
+	// Skip TLS certificate verification
+	TLSSkipVerify bool `yaml:"tlsSkipVerify"`
+	// Basic auth credentials
+	BasicAuth *basicAuthConfig `yaml:"basicAuth"`
+	// Name of the environment variable holding a bearer token to send as Authorization header
+	BearerTokenEnv string `yaml:"bearerTokenEnv"`
+}
+
+type basicAuthConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Notifier delivers fired alerts to an external system
+type Notifier interface {
+	Notify(ctx context.Context, alerts []alert) error
+}
+
+// newNotifier builds the Notifier described by cfg, or nil when cfg is unset
+func newNotifier(cfg *notifierConfig) (Notifier, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("notifier: url is required")
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.TLSSkipVerify}, // nolint: gosec
+		},
+	}
+	switch cfg.Type {
+	case notifierWebhook, "":
+		return &webhookNotifier{cfg: cfg, client: client}, nil
+	case notifierAlertmanager:
+		return &alertmanagerNotifier{cfg: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("notifier: unsupported type %q", cfg.Type)
+	}
+}
+
+func (cfg *notifierConfig) applyAuth(req *http.Request) {
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.BasicAuth != nil {
+		req.SetBasicAuth(cfg.BasicAuth.Username, cfg.BasicAuth.Password)
+	}
+	if cfg.BearerTokenEnv != "" {
+		if token := os.Getenv(cfg.BearerTokenEnv); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
+func (cfg *notifierConfig) post(ctx context.Context, client *http.Client, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notifier: error building request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	cfg.applyAuth(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notifier: error posting to %s: %w", cfg.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier: %s returned status %s", cfg.URL, resp.Status)
+	}
+	return nil
+}
+
+// webhookNotifier POSTs the fired alerts as a JSON array to a generic webhook URL
+type webhookNotifier struct {
+	cfg    *notifierConfig
+	client *http.Client
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alerts []alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("notifier: error marshalling alerts: %w", err)
+	}
+	log.Debugf("Notifying %d alerts to webhook %s", len(alerts), n.cfg.URL)
+	return n.cfg.post(ctx, n.client, "application/json", body)
+}
+
+// alertmanagerPayload is a single entry of the Alertmanager v2 /api/v2/alerts request body
+type alertmanagerPayload struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"`
+}
+
+// alertmanagerNotifier pushes fired alerts to a Prometheus Alertmanager v2 endpoint
+type alertmanagerNotifier struct {
+	cfg    *notifierConfig
+	client *http.Client
+}
+
+func (n *alertmanagerNotifier) Notify(ctx context.Context, alerts []alert) error {
+	payload := make([]alertmanagerPayload, 0, len(alerts))
+	for _, a := range alerts {
+		labels := map[string]string{
+			"alertname": a.RuleName,
+			"severity":  string(a.Severity),
+			"uuid":      a.UUID,
+		}
+		for k, v := range a.Labels {
+			labels[k] = v
+		}
+		annotations := map[string]string{"description": a.Description}
+		for k, v := range a.Annotations {
+			annotations[k] = v
+		}
+		entry := alertmanagerPayload{
+			Labels:      labels,
+			Annotations: annotations,
+			StartsAt:    a.Timestamp,
+		}
+		if runDuration, err := time.ParseDuration(a.For); err == nil {
+			endsAt := a.Timestamp.Add(runDuration)
+			entry.EndsAt = &endsAt
+		}
+		payload = append(payload, entry)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("notifier: error marshalling alerts: %w", err)
+	}
+	log.Debugf("Notifying %d alerts to alertmanager %s", len(alerts), n.cfg.URL)
+	return n.cfg.post(ctx, n.client, "application/json", body)
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"High CPU usage on {{$labels.pod}}": "high_cpu_usage_on_labels_pod",
+		"":                                  alertMetricName,
+		"!!!":                               alertMetricName,
+	}
+	for desc, want := range cases {
+		if got := slugify(desc); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", desc, got, want)
+		}
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if n, err := newNotifier(nil); n != nil || err != nil {
+		t.Fatalf("expected a nil notifier and no error for a nil config, got %v, %v", n, err)
+	}
+	if _, err := newNotifier(&notifierConfig{}); err == nil {
+		t.Fatalf("expected an error when url is empty")
+	}
+	n, err := newNotifier(&notifierConfig{Type: notifierWebhook, URL: "http://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := n.(*webhookNotifier); !ok {
+		t.Fatalf("expected a webhookNotifier, got %T", n)
+	}
+	n, err = newNotifier(&notifierConfig{Type: notifierAlertmanager, URL: "http://example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := n.(*alertmanagerNotifier); !ok {
+		t.Fatalf("expected an alertmanagerNotifier, got %T", n)
+	}
+	if _, err := newNotifier(&notifierConfig{Type: "bogus", URL: "http://example.com"}); err == nil {
+		t.Fatalf("expected an error for an unsupported notifier type")
+	}
+}
+
+func TestWebhookNotifierPostsAlerts(t *testing.T) {
+	var gotAuth, gotHeader string
+	var gotBody []alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotHeader = r.Header.Get("X-Custom")
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("error decoding request body: %s", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := &notifierConfig{
+		Type:      notifierWebhook,
+		URL:       srv.URL,
+		Headers:   map[string]string{"X-Custom": "value"},
+		BasicAuth: &basicAuthConfig{Username: "user", Password: "pass"},
+	}
+	n, err := newNotifier(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	alerts := []alert{{UUID: "uuid", RuleName: "high_cpu", Severity: sevWarn}}
+	if err := n.Notify(context.Background(), alerts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if gotHeader != "value" {
+		t.Errorf("expected custom header to be forwarded, got %q", gotHeader)
+	}
+	if gotAuth == "" {
+		t.Errorf("expected basic auth to be set")
+	}
+	if len(gotBody) != 1 || gotBody[0].RuleName != "high_cpu" {
+		t.Errorf("expected the posted body to carry the alert, got %+v", gotBody)
+	}
+}
+
+func TestWebhookNotifierNonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n, err := newNotifier(&notifierConfig{Type: notifierWebhook, URL: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := n.Notify(context.Background(), []alert{{}}); err == nil {
+		t.Fatalf("expected an error when the endpoint returns a non-2xx status")
+	}
+}
+
+func TestAlertmanagerNotifierPayload(t *testing.T) {
+	var gotPayload []alertmanagerPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&gotPayload); err != nil {
+			t.Errorf("error decoding request body: %s", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n, err := newNotifier(&notifierConfig{Type: notifierAlertmanager, URL: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ts := time.Unix(1700000000, 0).UTC()
+	alerts := []alert{
+		{UUID: "uuid", RuleName: "high_cpu", Severity: sevWarn, Timestamp: ts, For: "2m0s"},
+		{UUID: "uuid", RuleName: "low_memory", Severity: sevCritical, Timestamp: ts, Labels: map[string]string{"alertname": "custom-name"}},
+	}
+	if err := n.Notify(context.Background(), alerts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(gotPayload) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(gotPayload))
+	}
+	if gotPayload[0].Labels["alertname"] != "high_cpu" {
+		t.Errorf("expected alertname to default to the rule-derived slug, got %q", gotPayload[0].Labels["alertname"])
+	}
+	if gotPayload[0].EndsAt == nil || !gotPayload[0].EndsAt.Equal(ts.Add(2*time.Minute)) {
+		t.Errorf("expected endsAt to be derived from the for duration, got %v", gotPayload[0].EndsAt)
+	}
+	if gotPayload[1].Labels["alertname"] != "custom-name" {
+		t.Errorf("expected an explicit alertname label to override the rule-derived slug, got %q", gotPayload[1].Labels["alertname"])
+	}
+	if gotPayload[1].EndsAt != nil {
+		t.Errorf("expected no endsAt without a for duration, got %v", gotPayload[1].EndsAt)
+	}
+}
@@ -42,6 +42,41 @@ var (
 	}
 )
 
+// patchConflictPolicy controls how patchHandler reacts to a 409 Conflict returned by
+// server-side apply when another field manager owns a field
+type patchConflictPolicy string
+
+const (
+	conflictPolicyFail  patchConflictPolicy = "fail"
+	conflictPolicyForce patchConflictPolicy = "force"
+	conflictPolicyRetry patchConflictPolicy = "retry"
+)
+
+// resourceVersionVar is the template variable carrying the resourceVersion of the object
+// being patched, refreshed on every conflict-retry attempt
+const resourceVersionVar = "resourceVersion"
+
+const defaultFieldManager = "kube-controller-manager"
+
+var (
+	defaultConflictPolicy   = conflictPolicyFail
+	supportedConflictPolicy = map[patchConflictPolicy]struct{}{
+		conflictPolicyFail:  {},
+		conflictPolicyForce: {},
+		conflictPolicyRetry: {},
+	}
+)
+
+// applyPatchCBORType is the CBOR content type 1.29+ clusters accept for server-side apply.
+// kube-burner doesn't encode patch bodies as CBOR (renderPatchData only produces YAML/JSON),
+// so this type is rejected up front in setupPatchJob rather than silently mis-sent.
+const applyPatchCBORType = types.PatchType("application/apply-patch+cbor")
+
+// isApplyPatch reports whether patchType performs a server-side apply
+func isApplyPatch(patchType string) bool {
+	return patchType == string(types.ApplyPatchType)
+}
+
 func setupPatchJob(jobConfig config.Job) Executor {
 	var f io.Reader
 	var err error
@@ -87,12 +122,29 @@ func setupPatchJob(jobConfig config.Job) Executor {
 		if len(o.PatchType) == 0 {
 			log.Fatalln("Empty Patch Type not allowed")
 		}
+		if types.PatchType(o.PatchType) == applyPatchCBORType {
+			log.Fatalf("Patch type %s is not supported: kube-burner does not CBOR-encode the patch body, "+
+				"use %s instead", applyPatchCBORType, types.ApplyPatchType)
+		}
+		conflictPolicy := patchConflictPolicy(o.ConflictPolicy)
+		if len(conflictPolicy) == 0 {
+			conflictPolicy = defaultConflictPolicy
+		}
+		if _, ok := supportedConflictPolicy[conflictPolicy]; !ok {
+			log.Fatalf("Unsupported conflict policy: %s", conflictPolicy)
+		}
+		fieldManager := o.FieldManager
+		if fieldManager == "" {
+			fieldManager = defaultFieldManager
+		}
 		obj := object{
-			gvr:           mapping.Resource,
-			objectSpec:    t,
-			Object:        o,
-			labelSelector: o.LabelSelector,
-			patchType:     o.PatchType,
+			gvr:            mapping.Resource,
+			objectSpec:     t,
+			Object:         o,
+			labelSelector:  o.LabelSelector,
+			patchType:      o.PatchType,
+			conflictPolicy: conflictPolicy,
+			fieldManager:   fieldManager,
 		}
 		obj.Namespaced = mapping.Scope.Name() == meta.RESTScopeNameNamespace
 		log.Infof("Job %s: Patch %s with selector %s", jobConfig.Name, gvk.Kind, labels.Set(obj.labelSelector))
@@ -187,53 +239,119 @@ func (ex *Executor) runParallel() {
 	ex.waitForObjects("", waitRateLimiter)
 }
 
-func (ex *Executor) patchHandler(obj object, originalItem unstructured.Unstructured,
-	iteration int, wg *sync.WaitGroup) {
-
-	defer wg.Done()
-	// There are several patch modes. Three of them are client-side, and one
-	// of them is server-side.
-	var data []byte
-	patchOptions := metav1.PatchOptions{}
+// buildPatchTemplateData assembles the template variables patch templates are rendered
+// with, including the object's current resourceVersion so conflict retries can refresh it
+func (ex *Executor) buildPatchTemplateData(obj object, iteration int, resourceVersion string) map[string]interface{} {
+	templateData := map[string]interface{}{
+		jobName:      ex.Name,
+		jobIteration: iteration,
+		jobUUID:      ex.uuid,
+	}
+	for k, v := range obj.InputVars {
+		templateData[k] = v
+	}
+	if resourceVersion != "" {
+		templateData[resourceVersionVar] = resourceVersion
+	}
+	return templateData
+}
 
+// renderPatchData builds the patch body. JSON templates are returned as read, without
+// rendering; other templates are rendered and converted to JSON, unless it's a
+// server-side apply, whose rendered YAML is sent as-is
+func (ex *Executor) renderPatchData(obj object, templateData map[string]interface{}, patchOptions *metav1.PatchOptions) ([]byte, error) {
 	if strings.HasSuffix(obj.ObjectTemplate, "json") {
-		if obj.patchType == string(types.ApplyPatchType) {
+		if isApplyPatch(obj.patchType) {
 			log.Fatalf("Apply patch type requires YAML")
 		}
-		data = obj.objectSpec
-	} else {
-		// Processing template
-		templateData := map[string]interface{}{
-			jobName:      ex.Name,
-			jobIteration: iteration,
-			jobUUID:      ex.uuid,
-		}
-		for k, v := range obj.InputVars {
-			templateData[k] = v
-		}
+		return obj.objectSpec, nil
+	}
 
-		templateOption := util.MissingKeyError
-		if ex.DefaultMissingKeysWithZero {
-			templateOption = util.MissingKeyZero
-		}
+	templateOption := util.MissingKeyError
+	if ex.DefaultMissingKeysWithZero {
+		templateOption = util.MissingKeyZero
+	}
 
-		renderedObj, err := util.RenderTemplate(obj.objectSpec, templateData, templateOption)
-		if err != nil {
-			log.Fatalf("Template error in %s: %s", obj.ObjectTemplate, err)
-		}
+	renderedObj, err := util.RenderTemplate(obj.objectSpec, templateData, templateOption)
+	if err != nil {
+		log.Fatalf("Template error in %s: %s", obj.ObjectTemplate, err)
+	}
 
-		// Converting to JSON if patch type is not Apply
-		if obj.patchType == string(types.ApplyPatchType) {
-			data = renderedObj
-			patchOptions.FieldManager = "kube-controller-manager"
-		} else {
-			newObject := &unstructured.Unstructured{}
-			yamlToUnstructured(obj.ObjectTemplate, renderedObj, newObject)
-			data, err = newObject.MarshalJSON()
-			if err != nil {
-				log.Errorf("Error converting patch to JSON")
+	// Converting to JSON if patch type is not Apply
+	if isApplyPatch(obj.patchType) {
+		patchOptions.FieldManager = obj.fieldManager
+		return renderedObj, nil
+	}
+	newObject := &unstructured.Unstructured{}
+	yamlToUnstructured(obj.ObjectTemplate, renderedObj, newObject)
+	data, err := newObject.MarshalJSON()
+	if err != nil {
+		log.Errorf("Error converting patch to JSON")
+	}
+	return data, nil
+}
+
+// doPatch issues the actual patch request, namespaced or cluster-scoped depending on obj
+func (ex *Executor) doPatch(obj object, ns, name string, data []byte, patchOptions metav1.PatchOptions) (*unstructured.Unstructured, error) {
+	if obj.Namespaced {
+		return DynamicClient.Resource(obj.gvr).Namespace(ns).
+			Patch(context.TODO(), name, types.PatchType(obj.patchType), data, patchOptions)
+	}
+	return DynamicClient.Resource(obj.gvr).
+		Patch(context.TODO(), name, types.PatchType(obj.patchType), data, patchOptions)
+}
+
+// getCurrentObject fetches the live object, used to pick up its resourceVersion before a
+// conflict-retry attempt
+func (ex *Executor) getCurrentObject(obj object, ns, name string) (*unstructured.Unstructured, error) {
+	if obj.Namespaced {
+		return DynamicClient.Resource(obj.gvr).Namespace(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	}
+	return DynamicClient.Resource(obj.gvr).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// retryPatch refetches the object's resourceVersion and re-renders the template before
+// every attempt, backing off exponentially until ex.MaxWaitTimeout is reached
+func (ex *Executor) retryPatch(obj object, ns string, originalItem unstructured.Unstructured,
+	iteration int, patchOptions metav1.PatchOptions) (*unstructured.Unstructured, error) {
+
+	name := originalItem.GetName()
+	var uns *unstructured.Unstructured
+	err := util.RetryWithExponentialBackOff(func() (done bool, err error) {
+		current, getErr := ex.getCurrentObject(obj, ns, name)
+		if getErr != nil {
+			log.Errorf("Error refetching %s/%s for conflict retry: %s", originalItem.GetKind(), name, getErr)
+			return false, nil
+		}
+		templateData := ex.buildPatchTemplateData(obj, iteration, current.GetResourceVersion())
+		data, renderErr := ex.renderPatchData(obj, templateData, &patchOptions)
+		if renderErr != nil {
+			return false, renderErr
+		}
+		uns, err = ex.doPatch(obj, ns, name, data, patchOptions)
+		if err != nil {
+			if errors.IsConflict(err) {
+				log.Warnf("Conflict patching %s/%s, retrying: %s", originalItem.GetKind(), name, err)
+				return false, nil
 			}
+			return false, err
 		}
+		return true, nil
+	}, 1*time.Second, 3, 0, ex.MaxWaitTimeout)
+	return uns, err
+}
+
+func (ex *Executor) patchHandler(obj object, originalItem unstructured.Unstructured,
+	iteration int, wg *sync.WaitGroup) {
+
+	defer wg.Done()
+	// There are several patch modes. Three of them are client-side, and one
+	// of them is server-side.
+	patchOptions := metav1.PatchOptions{}
+	templateData := ex.buildPatchTemplateData(obj, iteration, "")
+	data, err := ex.renderPatchData(obj, templateData, &patchOptions)
+	if err != nil {
+		log.Fatalf("Template error in %s: %s", obj.ObjectTemplate, err)
 	}
 
 	ns := originalItem.GetNamespace()
@@ -241,16 +359,17 @@ func (ex *Executor) patchHandler(obj object, originalItem unstructured.Unstructu
 		originalItem.GetName(), ns)
 	ex.limiter.Wait(context.TODO())
 
-	var uns *unstructured.Unstructured
-	var err error
-	if obj.Namespaced {
-		uns, err = DynamicClient.Resource(obj.gvr).Namespace(ns).
-			Patch(context.TODO(), originalItem.GetName(),
-				types.PatchType(obj.patchType), data, patchOptions)
-	} else {
-		uns, err = DynamicClient.Resource(obj.gvr).
-			Patch(context.TODO(), originalItem.GetName(),
-				types.PatchType(obj.patchType), data, patchOptions)
+	uns, err := ex.doPatch(obj, ns, originalItem.GetName(), data, patchOptions)
+	if err != nil && errors.IsConflict(err) && isApplyPatch(obj.patchType) {
+		switch obj.conflictPolicy {
+		case conflictPolicyForce:
+			log.Warnf("Conflict patching %s/%s, forcing ownership", originalItem.GetKind(), originalItem.GetName())
+			force := true
+			patchOptions.Force = &force
+			uns, err = ex.doPatch(obj, ns, originalItem.GetName(), data, patchOptions)
+		case conflictPolicyRetry:
+			uns, err = ex.retryPatch(obj, ns, originalItem, iteration, patchOptions)
+		}
 	}
 	if err != nil {
 		if errors.IsForbidden(err) {
@@ -0,0 +1,135 @@
+// Copyright 2022 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// withConflictingPatch swaps in a fake DynamicClient whose "patch" reactor returns a
+// Conflict on its first invocation, then succeeds, and returns the per-verb call counts
+func withConflictingPatch(t *testing.T, getResourceVersion string) (patchCalls, getCalls *int) {
+	t.Helper()
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	patchCalls = new(int)
+	getCalls = new(int)
+	client.PrependReactor("patch", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+		*patchCalls++
+		if *patchCalls == 1 {
+			return true, nil, apierrors.NewConflict(configMapGVR.GroupResource(), "cm1", fmt.Errorf("field manager conflict"))
+		}
+		result := &unstructured.Unstructured{}
+		result.SetAPIVersion("v1")
+		result.SetKind("ConfigMap")
+		result.SetName("cm1")
+		result.SetNamespace("ns1")
+		return true, result, nil
+	})
+	client.PrependReactor("get", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+		*getCalls++
+		result := &unstructured.Unstructured{}
+		result.SetAPIVersion("v1")
+		result.SetKind("ConfigMap")
+		result.SetName("cm1")
+		result.SetNamespace("ns1")
+		result.SetResourceVersion(getResourceVersion)
+		return true, result, nil
+	})
+	DynamicClient = client
+	t.Cleanup(func() { DynamicClient = nil })
+	return
+}
+
+func newConflictTestItem() unstructured.Unstructured {
+	item := unstructured.Unstructured{}
+	item.SetAPIVersion("v1")
+	item.SetKind("ConfigMap")
+	item.SetName("cm1")
+	item.SetNamespace("ns1")
+	return item
+}
+
+func newConflictTestObject(policy patchConflictPolicy) object {
+	return object{
+		gvr:            configMapGVR,
+		objectSpec:     []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm1\n"),
+		Namespaced:     true,
+		patchType:      string(types.ApplyPatchType),
+		conflictPolicy: policy,
+		fieldManager:   "kube-burner-test",
+	}
+}
+
+func TestPatchHandlerForcesOwnershipOnConflict(t *testing.T) {
+	patchCalls, _ := withConflictingPatch(t, "5")
+	ex := &Executor{limiter: rate.NewLimiter(rate.Inf, 1)}
+	obj := newConflictTestObject(conflictPolicyForce)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	ex.patchHandler(obj, newConflictTestItem(), 0, &wg)
+
+	if *patchCalls != 2 {
+		t.Fatalf("expected the conflict to trigger exactly one forced retry (2 patch calls), got %d", *patchCalls)
+	}
+}
+
+func TestPatchHandlerRetriesWithRefreshedResourceVersionOnConflict(t *testing.T) {
+	patchCalls, getCalls := withConflictingPatch(t, "7")
+	ex := &Executor{limiter: rate.NewLimiter(rate.Inf, 1), MaxWaitTimeout: 5 * time.Second}
+	obj := newConflictTestObject(conflictPolicyRetry)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	ex.patchHandler(obj, newConflictTestItem(), 0, &wg)
+
+	if *patchCalls != 2 {
+		t.Fatalf("expected the conflict to trigger exactly one retried patch (2 patch calls), got %d", *patchCalls)
+	}
+	if *getCalls != 1 {
+		t.Fatalf("expected retryPatch to refetch the object's resourceVersion once, got %d gets", *getCalls)
+	}
+}
+
+func TestPatchHandlerDoesNotRetryOnFail(t *testing.T) {
+	patchCalls, getCalls := withConflictingPatch(t, "5")
+	ex := &Executor{limiter: rate.NewLimiter(rate.Inf, 1)}
+	obj := newConflictTestObject(conflictPolicyFail)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	ex.patchHandler(obj, newConflictTestItem(), 0, &wg)
+
+	if *patchCalls != 1 {
+		t.Fatalf("expected the fail policy not to retry (1 patch call), got %d", *patchCalls)
+	}
+	if *getCalls != 0 {
+		t.Fatalf("expected the fail policy not to refetch the object, got %d gets", *getCalls)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Kube-burner Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burner
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsApplyPatch(t *testing.T) {
+	if !isApplyPatch(string(types.ApplyPatchType)) {
+		t.Errorf("expected %q to be recognized as a server-side apply patch", types.ApplyPatchType)
+	}
+	for _, patchType := range []string{string(types.JSONPatchType), string(types.MergePatchType), string(types.StrategicMergePatchType), string(applyPatchCBORType), ""} {
+		if isApplyPatch(patchType) {
+			t.Errorf("did not expect %q to be recognized as a server-side apply patch", patchType)
+		}
+	}
+}
+
+func TestSupportedConflictPolicy(t *testing.T) {
+	for _, policy := range []patchConflictPolicy{conflictPolicyFail, conflictPolicyForce, conflictPolicyRetry} {
+		if _, ok := supportedConflictPolicy[policy]; !ok {
+			t.Errorf("expected %q to be a supported conflict policy", policy)
+		}
+	}
+	if _, ok := supportedConflictPolicy[patchConflictPolicy("bogus")]; ok {
+		t.Errorf("did not expect %q to be a supported conflict policy", "bogus")
+	}
+	if defaultConflictPolicy != conflictPolicyFail {
+		t.Errorf("expected default conflict policy to be %q, got %q", conflictPolicyFail, defaultConflictPolicy)
+	}
+}
+
+func TestBuildPatchTemplateDataRefreshesResourceVersion(t *testing.T) {
+	ex := &Executor{uuid: "test-uuid"}
+	obj := object{InputVars: map[string]interface{}{"foo": "bar"}}
+
+	templateData := ex.buildPatchTemplateData(obj, 2, "")
+	if _, ok := templateData[resourceVersionVar]; ok {
+		t.Errorf("did not expect %s to be set without a resourceVersion", resourceVersionVar)
+	}
+
+	templateData = ex.buildPatchTemplateData(obj, 2, "42")
+	if templateData[resourceVersionVar] != "42" {
+		t.Errorf("expected %s to be refreshed to %q, got %q", resourceVersionVar, "42", templateData[resourceVersionVar])
+	}
+	if templateData[jobIteration] != 2 {
+		t.Errorf("expected %s to be 2, got %v", jobIteration, templateData[jobIteration])
+	}
+	if templateData[jobUUID] != "test-uuid" {
+		t.Errorf("expected %s to be %q, got %v", jobUUID, "test-uuid", templateData[jobUUID])
+	}
+	if templateData["foo"] != "bar" {
+		t.Errorf("expected input var %q to be carried through, got %v", "foo", templateData["foo"])
+	}
+}